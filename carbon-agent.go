@@ -11,6 +11,7 @@ import (
 	"runtime"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/lomik/go-carbon/carbon"
 	log "github.com/lomik/go-carbon/logging"
@@ -33,6 +34,8 @@ func httpServe(addr string) (func(), error) {
 		return nil, err
 	}
 
+	http.HandleFunc("/debug/log/level", log.ServeComponentLevels)
+
 	go http.Serve(listener, nil)
 	return func() { listener.Close() }, nil
 }
@@ -48,9 +51,10 @@ func init() {
 			case <-signalChan:
 				std := log.StandardLogger()
 				err := std.Reopen()
-				log.Infof("HUP received, reopen log %#v", std.Filename())
+				entry := log.WithFields(log.Fields{"component": "main", "filename": std.Filename()})
+				entry.Info("HUP received, reopen log")
 				if err != nil {
-					log.Errorf("Reopen log %#v failed: %s", std.Filename(), err.Error())
+					entry.WithError(err).Error("Reopen log failed")
 				}
 			}
 		}
@@ -86,6 +90,7 @@ func main() {
 	}
 
 	app := carbon.New(*configFile)
+	app.SetLogger(log.DefaultLogger())
 
 	if err = app.ParseConfig(); err != nil {
 		log.Fatal(err)
@@ -105,6 +110,10 @@ func main() {
 		log.Fatal(err)
 	}
 
+	if err := log.SetFormat(cfg.Common.LogFormat); err != nil {
+		log.Fatal(err)
+	}
+
 	// config parsed successfully. Exit in check-only mode
 	if *checkConfig {
 		return
@@ -114,6 +123,14 @@ func main() {
 		log.Fatal(err)
 	}
 
+	log.SetRotateConfig(log.RotateConfig{
+		MaxSizeMB:      cfg.Common.LogRotate.MaxSizeMB,
+		MaxAgeDays:     cfg.Common.LogRotate.MaxAgeDays,
+		MaxBackups:     cfg.Common.LogRotate.MaxBackups,
+		Compress:       cfg.Common.LogRotate.Compress,
+		RotateInterval: cfg.Common.LogRotate.RotateInterval,
+	})
+
 	if err := log.SetFile(cfg.Common.Logfile); err != nil {
 		log.Fatal(err)
 	}
@@ -167,10 +184,54 @@ func main() {
 		}
 	}
 
+	if cfg.Logging.Hooks.GELF.Enabled {
+		hook, err := log.NewGELFHook(log.GELFHookConfig{
+			Addr:      cfg.Logging.Hooks.GELF.Addr,
+			Level:     cfg.Logging.Hooks.GELF.Level,
+			QueueSize: cfg.Logging.Hooks.GELF.QueueSize,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.RegisterHook("gelf", hook)
+	}
+
+	if cfg.Logging.Hooks.HTTP.Enabled {
+		hook, err := log.NewHTTPHook(log.HTTPHookConfig{
+			URL:       cfg.Logging.Hooks.HTTP.URL,
+			Level:     cfg.Logging.Hooks.HTTP.Level,
+			QueueSize: cfg.Logging.Hooks.HTTP.QueueSize,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.RegisterHook("http", hook)
+	}
+
+	// Hook drop counters are polled once a minute and any increase is
+	// logged as a warning through the regular logger. They are not
+	// published as carbon.agents.* self-metrics.
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		last := make(map[string]uint64)
+
+		for range ticker.C {
+			for name, dropped := range log.HookDroppedCounts() {
+				if delta := dropped - last[name]; delta > 0 {
+					log.WithFields(log.Fields{"component": "main", "hook": name, "dropped": delta}).
+						Warn("logging hook dropped entries")
+				}
+				last[name] = dropped
+			}
+		}
+	}()
+
 	if err = app.Start(); err != nil {
 		log.Fatal(err)
 	} else {
-		log.Info("started")
+		log.WithField("component", "main").Info("started")
 	}
 
 	go func() {
@@ -179,6 +240,7 @@ func main() {
 		<-c
 		httpStop()
 		app.GraceStop()
+		log.CloseHooks()
 	}()
 
 	go func() {
@@ -186,16 +248,31 @@ func main() {
 		signal.Notify(c, syscall.SIGHUP)
 		for {
 			<-c
-			log.Info("HUP received. Reload config")
+			entry := log.WithField("component", "main")
+			entry.Info("HUP received. Reload config")
 			if err := app.ReloadConfig(); err != nil {
-				log.Errorf("Config reload failed: %s", err.Error())
+				entry.WithError(err).Error("Config reload failed")
 			} else {
-				log.Info("Config successfully reloaded")
+				if err := log.SetLevel(app.Config.Common.LogLevel); err != nil {
+					entry.WithError(err).Error("Applying reloaded log level failed")
+				}
+				if err := log.SetFormat(app.Config.Common.LogFormat); err != nil {
+					entry.WithError(err).Error("Applying reloaded log format failed")
+				}
+				log.SetRotateConfig(log.RotateConfig{
+					MaxSizeMB:      app.Config.Common.LogRotate.MaxSizeMB,
+					MaxAgeDays:     app.Config.Common.LogRotate.MaxAgeDays,
+					MaxBackups:     app.Config.Common.LogRotate.MaxBackups,
+					Compress:       app.Config.Common.LogRotate.Compress,
+					RotateInterval: app.Config.Common.LogRotate.RotateInterval,
+				})
+				entry.Info("Config successfully reloaded")
 			}
 		}
 	}()
 
 	app.Loop()
+	log.CloseHooks()
 
-	log.Info("stopped")
+	log.WithField("component", "main").Info("stopped")
 }
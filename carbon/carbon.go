@@ -0,0 +1,83 @@
+package carbon
+
+import (
+	"sync"
+
+	"github.com/lomik/go-carbon/logging"
+)
+
+// Carbon holds process-wide state for a single go-carbon instance: its
+// parsed Config plus the Logger the rest of the application should use.
+// The cache/persister/receivers subsystems from the real go-carbon have
+// not been ported into this checkout, so Start/Loop/GraceStop only
+// manage the process lifecycle carbon-agent drives them through.
+type Carbon struct {
+	ConfigFilename string
+	Config         *Config
+
+	logger logging.Logger
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// New returns a Carbon bound to configFilename, with default Config.
+// Call ParseConfig before Start to load the file.
+func New(configFilename string) *Carbon {
+	return &Carbon{
+		ConfigFilename: configFilename,
+		Config:         NewConfig(),
+		logger:         logging.NopLogger{},
+		stop:           make(chan struct{}),
+	}
+}
+
+// SetLogger replaces the Logger used for Carbon's own lifecycle
+// messages. carbon-agent calls this once at startup so Carbon logs
+// through the same FileLogger as everything else instead of going to
+// stderr.
+func (app *Carbon) SetLogger(logger logging.Logger) {
+	if logger == nil {
+		logger = logging.NopLogger{}
+	}
+	app.logger = logger
+}
+
+// ParseConfig loads Config from app.ConfigFilename. An empty filename
+// keeps the defaults from NewConfig.
+func (app *Carbon) ParseConfig() error {
+	if app.ConfigFilename == "" {
+		return nil
+	}
+
+	cfg := NewConfig()
+	if err := readConfig(app.ConfigFilename, cfg); err != nil {
+		return err
+	}
+	app.Config = cfg
+	return nil
+}
+
+// ReloadConfig re-parses app.ConfigFilename in place. Used by
+// carbon-agent's SIGHUP handler.
+func (app *Carbon) ReloadConfig() error {
+	return app.ParseConfig()
+}
+
+// Start brings up the carbon process. The cache/persister/receivers
+// subsystems this would normally wire together don't exist in this
+// checkout yet, so Start is currently just the startup log line.
+func (app *Carbon) Start() error {
+	app.logger.WithField("component", "carbon").Info("carbon started")
+	return nil
+}
+
+// Loop blocks until GraceStop is called.
+func (app *Carbon) Loop() {
+	<-app.stop
+}
+
+// GraceStop signals Loop to return.
+func (app *Carbon) GraceStop() {
+	app.stopOnce.Do(func() { close(app.stop) })
+}
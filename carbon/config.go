@@ -0,0 +1,88 @@
+package carbon
+
+import (
+	"io"
+	"os"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config mirrors go-carbon.conf. Only the sections carbon-agent's
+// logging integration depends on (common, pprof, logging hooks) are
+// defined here; the cache/persister/receivers sections from the real
+// go-carbon.conf have not been ported into this checkout yet.
+type Config struct {
+	Common  commonConfig  `toml:"common"`
+	Pprof   pprofConfig   `toml:"pprof"`
+	Logging loggingConfig `toml:"logging"`
+}
+
+type rotateConfig struct {
+	MaxSizeMB      int    `toml:"max-size-mb"`
+	MaxAgeDays     int    `toml:"max-age-days"`
+	MaxBackups     int    `toml:"max-backups"`
+	Compress       bool   `toml:"compress"`
+	RotateInterval string `toml:"rotate-interval"` // "", "hourly" or "daily"
+}
+
+type commonConfig struct {
+	User      string       `toml:"user"`
+	Logfile   string       `toml:"logfile"`
+	LogLevel  string       `toml:"log-level"`
+	LogFormat string       `toml:"log-format"`
+	LogRotate rotateConfig `toml:"log-rotate"`
+	MaxCPU    int          `toml:"max-cpu"`
+}
+
+type pprofConfig struct {
+	Enabled bool   `toml:"enabled"`
+	Listen  string `toml:"listen"`
+}
+
+type hookConfig struct {
+	Enabled   bool   `toml:"enabled"`
+	Level     string `toml:"level"`
+	QueueSize int    `toml:"queue-size"`
+	Addr      string `toml:"addr"` // gelf
+	URL       string `toml:"url"`  // http
+}
+
+type hooksConfig struct {
+	GELF hookConfig `toml:"gelf"`
+	HTTP hookConfig `toml:"http"`
+}
+
+type loggingConfig struct {
+	Hooks hooksConfig `toml:"hooks"`
+}
+
+// NewConfig returns a Config populated with the defaults go-carbon
+// ships with out of the box.
+func NewConfig() *Config {
+	return &Config{
+		Common: commonConfig{
+			Logfile:   "/var/log/go-carbon/go-carbon.log",
+			LogLevel:  "info",
+			LogFormat: "text",
+			MaxCPU:    1,
+		},
+		Pprof: pprofConfig{
+			Enabled: false,
+			Listen:  "localhost:7007",
+		},
+	}
+}
+
+// PrintConfig writes cfg to stdout as TOML, backing "-config-print-default".
+func PrintConfig(cfg *Config) error {
+	return writeConfig(os.Stdout, cfg)
+}
+
+func writeConfig(w io.Writer, cfg *Config) error {
+	return toml.NewEncoder(w).Encode(cfg)
+}
+
+func readConfig(filename string, cfg *Config) error {
+	_, err := toml.DecodeFile(filename, cfg)
+	return err
+}
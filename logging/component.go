@@ -0,0 +1,175 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+var (
+	componentLevelsMu sync.RWMutex
+	componentLevels   = map[string]logrus.Level{}
+)
+
+func splitComponentLevel(part string) (name, level string, ok bool) {
+	idx := strings.IndexByte(part, '=')
+	if idx < 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(part[:idx]), strings.TrimSpace(part[idx+1:]), true
+}
+
+// SetComponentLevels replaces the full set of per-component log level
+// overrides, e.g. {"cache": "debug", "persister": "info", "tcp": "warn"}.
+// Components without an override fall back to the standard logger's level.
+func SetComponentLevels(levels map[string]string) error {
+	parsed := make(map[string]logrus.Level, len(levels))
+	for name, lvl := range levels {
+		level, err := logrus.ParseLevel(lvl)
+		if err != nil {
+			return fmt.Errorf("logging: component %#v: %s", name, err)
+		}
+		parsed[name] = level
+	}
+
+	componentLevelsMu.Lock()
+	componentLevels = parsed
+	componentLevelsMu.Unlock()
+
+	return nil
+}
+
+// ComponentLevels returns a snapshot of the current per-component level
+// overrides, suitable for JSON encoding.
+func ComponentLevels() map[string]string {
+	componentLevelsMu.RLock()
+	defer componentLevelsMu.RUnlock()
+
+	out := make(map[string]string, len(componentLevels))
+	for name, level := range componentLevels {
+		out[name] = level.String()
+	}
+	return out
+}
+
+func componentLevel(name string) (logrus.Level, bool) {
+	componentLevelsMu.RLock()
+	defer componentLevelsMu.RUnlock()
+	level, ok := componentLevels[name]
+	return level, ok
+}
+
+// componentLogger tags every entry with a "component" field and applies
+// that component's level override, if any, instead of the standard
+// logger's level.
+type componentLogger struct {
+	name  string
+	entry *logrus.Entry
+}
+
+// ForComponent returns a Logger that tags entries with a "component"
+// field and honors that component's log level override, so a single
+// subsystem (e.g. "persister") can be dialed up to debug without
+// drowning in unrelated verbosity.
+func ForComponent(name string) Logger {
+	return &componentLogger{name: name, entry: std.Logger.WithField("component", name)}
+}
+
+func (c *componentLogger) level() logrus.Level {
+	if lvl, ok := componentLevel(c.name); ok {
+		return lvl
+	}
+	return std.Logger.Level
+}
+
+// log emits the entry directly through the shared logger's hooks and
+// output, bypassing logrus.Entry's own Debug/Info/... convenience
+// methods. Those gate on entry.Logger.Level, which is the *global*
+// level shared by every component — going through them would mean a
+// "persister=debug" override could never log below the global level,
+// defeating the point of a per-component override.
+//
+// entry.Logger is always std.Logger, so the write below is serialized
+// against std's own lock — the same lock Reopen holds while swapping
+// Out out from under us — instead of a second, uncoordinated mutex.
+func (c *componentLogger) log(level logrus.Level, args ...interface{}) {
+	if level > c.level() {
+		return
+	}
+
+	entry := c.entry.WithFields(logrus.Fields{})
+	entry.Time = time.Now()
+	entry.Level = level
+	entry.Message = fmt.Sprint(args...)
+
+	if entry.Logger.Hooks != nil {
+		entry.Logger.Hooks.Fire(level, entry)
+	}
+
+	line, err := entry.String()
+	if err != nil {
+		return
+	}
+
+	std.Lock()
+	io.WriteString(entry.Logger.Out, line)
+	std.Unlock()
+
+	if level <= logrus.FatalLevel {
+		os.Exit(1)
+	}
+}
+
+func (c *componentLogger) Debug(args ...interface{}) { c.log(logrus.DebugLevel, args...) }
+func (c *componentLogger) Info(args ...interface{})  { c.log(logrus.InfoLevel, args...) }
+func (c *componentLogger) Warn(args ...interface{})  { c.log(logrus.WarnLevel, args...) }
+func (c *componentLogger) Error(args ...interface{}) { c.log(logrus.ErrorLevel, args...) }
+func (c *componentLogger) Fatal(args ...interface{}) { c.log(logrus.FatalLevel, args...) }
+
+func (c *componentLogger) WithField(key string, value interface{}) Logger {
+	return &componentLogger{name: c.name, entry: c.entry.WithField(key, value)}
+}
+
+func (c *componentLogger) WithFields(fields Fields) Logger {
+	return &componentLogger{name: c.name, entry: c.entry.WithFields(logrus.Fields(fields))}
+}
+
+func (c *componentLogger) WithError(err error) Logger {
+	return &componentLogger{name: c.name, entry: c.entry.WithField("error", err)}
+}
+
+var _ Logger = (*componentLogger)(nil)
+
+// ServeComponentLevels is an http.HandlerFunc exposing the current
+// per-component log level map as JSON. It is meant to be registered on
+// the existing pprof listener at "/debug/log/level": GET returns the
+// current map, PUT replaces it, for on-call debugging on a live process.
+func ServeComponentLevels(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ComponentLevels())
+	case http.MethodPut:
+		var levels map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&levels); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := SetComponentLevels(levels); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ComponentLevels())
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestSplitComponentLevel(t *testing.T) {
+	cases := []struct {
+		part      string
+		name      string
+		level     string
+		wantMatch bool
+	}{
+		{part: "persister=debug", name: "persister", level: "debug", wantMatch: true},
+		{part: " tcp = warn ", name: "tcp", level: "warn", wantMatch: true},
+		{part: "debug", wantMatch: false},
+	}
+
+	for _, c := range cases {
+		name, level, ok := splitComponentLevel(c.part)
+		if ok != c.wantMatch {
+			t.Errorf("splitComponentLevel(%q) ok = %v, want %v", c.part, ok, c.wantMatch)
+			continue
+		}
+		if ok && (name != c.name || level != c.level) {
+			t.Errorf("splitComponentLevel(%q) = (%q, %q), want (%q, %q)", c.part, name, level, c.name, c.level)
+		}
+	}
+}
+
+func TestSetLevelClearsStaleComponentOverrides(t *testing.T) {
+	defer SetComponentLevels(map[string]string{})
+	defer logrus.SetLevel(logrus.InfoLevel)
+
+	if err := SetLevel("info,persister=debug"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := componentLevel("persister"); !ok {
+		t.Fatal("expected persister override to be set")
+	}
+
+	if err := SetLevel("info"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := componentLevel("persister"); ok {
+		t.Error("expected a reload with no overrides to clear the stale persister override")
+	}
+}
+
+func TestForComponentLogsBelowGlobalLevel(t *testing.T) {
+	defer SetComponentLevels(map[string]string{})
+	defer logrus.SetLevel(logrus.InfoLevel)
+
+	logrus.SetLevel(logrus.InfoLevel)
+	if err := SetComponentLevels(map[string]string{"persister": "debug"}); err != nil {
+		t.Fatal(err)
+	}
+
+	Test(func(out TestOut) {
+		ForComponent("persister").Debug("rotating segment")
+
+		if !strings.Contains(out.String(), "rotating segment") {
+			t.Errorf("expected a persister=debug override to emit a Debug line while the global level is info, got %q", out.String())
+		}
+	})
+}
+
+func TestForComponentDefaultsToGlobalLevel(t *testing.T) {
+	defer SetComponentLevels(map[string]string{})
+	defer logrus.SetLevel(logrus.InfoLevel)
+
+	logrus.SetLevel(logrus.InfoLevel)
+
+	Test(func(out TestOut) {
+		ForComponent("cache").Debug("should be filtered")
+
+		if strings.Contains(out.String(), "should be filtered") {
+			t.Error("expected Debug to be filtered for a component with no override while the global level is info")
+		}
+	})
+}
@@ -0,0 +1,266 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+var (
+	hooksMu sync.Mutex
+	hooks   = map[string]logrus.Hook{}
+)
+
+// RegisterHook registers a logrus.Hook under name on the standard
+// logger, replacing any hook previously registered under the same name.
+// This is the extension point config-driven registration
+// ([logging.hooks.gelf], [logging.hooks.http]) builds on top of, but
+// it's equally usable directly by embedders.
+func RegisterHook(name string, h logrus.Hook) {
+	hooksMu.Lock()
+	old := hooks[name]
+	hooks[name] = h
+	hooksMu.Unlock()
+
+	replaceHook(std.Logger, old, h)
+	if closer, ok := old.(hookCloser); ok {
+		closer.Close()
+	}
+}
+
+// UnregisterHook removes a previously registered hook, closing it if it
+// supports Close() so any buffered entries are flushed.
+func UnregisterHook(name string) {
+	hooksMu.Lock()
+	old, ok := hooks[name]
+	delete(hooks, name)
+	hooksMu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	replaceHook(std.Logger, old, nil)
+	if closer, ok := old.(hookCloser); ok {
+		closer.Close()
+	}
+}
+
+// CloseHooks flushes and closes every registered hook. It should be
+// called as part of app.GraceStop() so buffered events (Sentry/GELF/HTTP
+// deliveries) are sent before the process exits.
+func CloseHooks() {
+	hooksMu.Lock()
+	names := make([]string, 0, len(hooks))
+	for name := range hooks {
+		names = append(names, name)
+	}
+	hooksMu.Unlock()
+
+	for _, name := range names {
+		UnregisterHook(name)
+	}
+}
+
+type hookCloser interface {
+	Close() error
+}
+
+// HookDroppedCounts returns the current dropped-entry counters for every
+// registered AsyncHook, keyed by hook name. carbon-agent polls it once a
+// minute and logs any increase as a warning; the counts are not published
+// as carbon.agents.* metrics.
+func HookDroppedCounts() map[string]uint64 {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+
+	out := make(map[string]uint64, len(hooks))
+	for name, h := range hooks {
+		if a, ok := h.(*AsyncHook); ok {
+			out[name] = a.Dropped()
+		}
+	}
+	return out
+}
+
+// AsyncHook wraps a slow/remote delivery function (Sentry, GELF, HTTP,
+// ...) with a bounded in-memory queue so that logging a message never
+// blocks on network I/O. Once the queue is full, further entries are
+// dropped and counted rather than applying backpressure to the caller.
+type AsyncHook struct {
+	levels  []logrus.Level
+	queue   chan *logrus.Entry
+	dropped uint64
+	deliver func(*logrus.Entry) error
+	wg      sync.WaitGroup
+}
+
+// NewAsyncHook starts a delivery goroutine backed by a queue of
+// queueSize entries and returns the resulting hook.
+func NewAsyncHook(levels []logrus.Level, queueSize int, deliver func(*logrus.Entry) error) *AsyncHook {
+	h := &AsyncHook{
+		levels:  levels,
+		queue:   make(chan *logrus.Entry, queueSize),
+		deliver: deliver,
+	}
+
+	h.wg.Add(1)
+	go h.run()
+
+	return h
+}
+
+func (h *AsyncHook) run() {
+	defer h.wg.Done()
+	for entry := range h.queue {
+		// Delivery errors aren't actionable for a background log sink;
+		// there's nothing useful to do but drop them on the floor.
+		h.deliver(entry)
+	}
+}
+
+func (h *AsyncHook) Levels() []logrus.Level {
+	return h.levels
+}
+
+func (h *AsyncHook) Fire(entry *logrus.Entry) error {
+	clone := *entry
+	select {
+	case h.queue <- &clone:
+	default:
+		atomic.AddUint64(&h.dropped, 1)
+	}
+	return nil
+}
+
+// Dropped returns the number of entries dropped so far because the
+// queue was full.
+func (h *AsyncHook) Dropped() uint64 {
+	return atomic.LoadUint64(&h.dropped)
+}
+
+// Close drains the queue and stops the delivery goroutine.
+func (h *AsyncHook) Close() error {
+	close(h.queue)
+	h.wg.Wait()
+	return nil
+}
+
+func levelsAtOrAbove(threshold logrus.Level) []logrus.Level {
+	var out []logrus.Level
+	for _, l := range logrus.AllLevels {
+		if l <= threshold {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func parseHookLevel(level string) (logrus.Level, error) {
+	if level == "" {
+		return logrus.ErrorLevel, nil
+	}
+	return logrus.ParseLevel(level)
+}
+
+// GELFHookConfig configures the in-tree GELF/UDP hook
+// ([logging.hooks.gelf]).
+type GELFHookConfig struct {
+	Addr      string
+	Level     string // minimum level to forward; defaults to "error"
+	QueueSize int    // defaults to 1000
+}
+
+// NewGELFHook dials addr (UDP) and returns an AsyncHook that ships
+// entries at cfg.Level and above as GELF 1.1 messages.
+func NewGELFHook(cfg GELFHookConfig) (*AsyncHook, error) {
+	level, err := parseHookLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.Dial("udp", cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = 1000
+	}
+
+	return NewAsyncHook(levelsAtOrAbove(level), queueSize, func(entry *logrus.Entry) error {
+		msg := map[string]interface{}{
+			"version":       "1.1",
+			"host":          hostname,
+			"short_message": entry.Message,
+			"timestamp":     float64(entry.Time.UnixNano()) / 1e9,
+			"level":         int(syslogSeverity(entry.Level)),
+		}
+		for k, v := range entry.Data {
+			msg["_"+k] = v
+		}
+
+		encoded, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+
+		_, err = conn.Write(encoded)
+		return err
+	}), nil
+}
+
+// HTTPHookConfig configures the in-tree generic HTTP-JSON hook
+// ([logging.hooks.http]).
+type HTTPHookConfig struct {
+	URL       string
+	Level     string
+	QueueSize int
+}
+
+// NewHTTPHook POSTs a JSON document per entry to cfg.URL for entries at
+// cfg.Level and above.
+func NewHTTPHook(cfg HTTPHookConfig) (*AsyncHook, error) {
+	level, err := parseHookLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = 1000
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	return NewAsyncHook(levelsAtOrAbove(level), queueSize, func(entry *logrus.Entry) error {
+		payload := map[string]interface{}{
+			"timestamp": entry.Time.Format(time.RFC3339Nano),
+			"level":     entry.Level.String(),
+			"message":   entry.Message,
+		}
+		for k, v := range entry.Data {
+			payload[k] = v
+		}
+
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Post(cfg.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}), nil
+}
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestAsyncHookDropsWhenQueueFull(t *testing.T) {
+	block := make(chan struct{})
+	var delivered sync.WaitGroup
+	var firstDelivery sync.Once
+	delivered.Add(1)
+
+	hook := NewAsyncHook(logrus.AllLevels, 1, func(entry *logrus.Entry) error {
+		// Close drains the queued entry too, so only the first delivery
+		// may release the WaitGroup.
+		firstDelivery.Do(delivered.Done)
+		<-block
+		return nil
+	})
+	defer func() {
+		close(block)
+		hook.Close()
+	}()
+
+	entry := &logrus.Entry{Logger: logrus.StandardLogger()}
+
+	// The first Fire is picked up by the delivery goroutine immediately
+	// and blocks there; the second fills the size-1 queue; the third has
+	// nowhere to go and must be counted as dropped.
+	hook.Fire(entry)
+	delivered.Wait()
+	hook.Fire(entry)
+	hook.Fire(entry)
+
+	if got := hook.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1", got)
+	}
+}
+
+func TestLevelsAtOrAbove(t *testing.T) {
+	levels := levelsAtOrAbove(logrus.WarnLevel)
+
+	want := map[logrus.Level]bool{
+		logrus.PanicLevel: true,
+		logrus.FatalLevel: true,
+		logrus.ErrorLevel: true,
+		logrus.WarnLevel:  true,
+	}
+
+	if len(levels) != len(want) {
+		t.Fatalf("levelsAtOrAbove(Warn) = %v, want levels %v", levels, want)
+	}
+	for _, l := range levels {
+		if !want[l] {
+			t.Errorf("levelsAtOrAbove(Warn) unexpectedly included %v", l)
+		}
+	}
+}
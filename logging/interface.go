@@ -0,0 +1,90 @@
+package logging
+
+import "github.com/Sirupsen/logrus"
+
+// Logger is the interface go-carbon depends on instead of talking to
+// logrus directly. Embedders that use go-carbon as a library can provide
+// their own implementation (zap, zerolog, an in-house logger, ...)
+// without pulling logrus into their binary.
+//
+// carbon.Carbon accepts one via SetLogger (see carbon-agent.go), which
+// is the seam persister/receivers/cache are expected to pull their
+// logger from as they're converted off the package-level log.* calls.
+type Logger interface {
+	Debug(args ...interface{})
+	Info(args ...interface{})
+	Warn(args ...interface{})
+	Error(args ...interface{})
+	Fatal(args ...interface{})
+
+	WithField(key string, value interface{}) Logger
+	WithFields(fields Fields) Logger
+	WithError(err error) Logger
+}
+
+// entryLogger adapts a *logrus.Entry to the Logger interface, so that
+// chained WithField/WithFields/WithError calls keep returning a Logger
+// instead of leaking the logrus type to callers.
+type entryLogger struct {
+	*logrus.Entry
+}
+
+func (e *entryLogger) WithField(key string, value interface{}) Logger {
+	return &entryLogger{e.Entry.WithField(key, value)}
+}
+
+func (e *entryLogger) WithFields(fields Fields) Logger {
+	return &entryLogger{e.Entry.WithFields(logrus.Fields(fields))}
+}
+
+func (e *entryLogger) WithError(err error) Logger {
+	return &entryLogger{e.Entry.WithField("error", err)}
+}
+
+// WithField creates a Logger with a single field attached.
+func (l *FileLogger) WithField(key string, value interface{}) Logger {
+	return &entryLogger{l.Logger.WithField(key, value)}
+}
+
+// WithFields creates a Logger with multiple fields attached.
+func (l *FileLogger) WithFields(fields Fields) Logger {
+	return &entryLogger{l.Logger.WithFields(logrus.Fields(fields))}
+}
+
+// WithError creates a Logger with an "error" field attached.
+func (l *FileLogger) WithError(err error) Logger {
+	return &entryLogger{l.Logger.WithField("error", err)}
+}
+
+// DefaultLogger returns the package's logrus-backed standard logger as a
+// Logger, for callers that construct go-carbon components without
+// supplying one of their own.
+func DefaultLogger() Logger {
+	return std
+}
+
+// NopLogger is a Logger implementation that discards everything. Useful
+// in unit tests that exercise a code path but don't care about its log
+// output.
+type NopLogger struct{}
+
+// NewNopLogger returns a Logger that discards all log entries.
+func NewNopLogger() Logger {
+	return NopLogger{}
+}
+
+func (NopLogger) Debug(args ...interface{}) {}
+func (NopLogger) Info(args ...interface{})  {}
+func (NopLogger) Warn(args ...interface{})  {}
+func (NopLogger) Error(args ...interface{}) {}
+func (NopLogger) Fatal(args ...interface{}) {}
+
+func (n NopLogger) WithField(key string, value interface{}) Logger { return n }
+func (n NopLogger) WithFields(fields Fields) Logger                { return n }
+func (n NopLogger) WithError(err error) Logger                     { return n }
+
+var (
+	_ Logger = (*FileLogger)(nil)
+	_ Logger = (*entryLogger)(nil)
+	_ Logger = NopLogger{}
+)
@@ -2,12 +2,16 @@ package logging
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/user"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/howeyc/fsnotify"
@@ -21,27 +25,112 @@ func StandardLogger() *FileLogger {
 
 type Fields logrus.Fields
 
+// Format is the on-disk/stdout encoding used by a FileLogger.
+type Format string
+
+// Supported values for cfg.Common.LogFormat.
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
 // FileLogger wrapper
 type FileLogger struct {
 	sync.RWMutex
 	filename    string
 	fd          *os.File
+	rotateCfg   RotateConfig
+	rotating    *rotatingFile
+	format      Format
+	syslogHook  *SyslogHook
 	watcherDone chan bool
 	*logrus.Logger
 }
 
 // NewFileLogger create instance FileLogger
 func NewFileLogger() *FileLogger {
-	logrus.SetFormatter(&TextFormatter{})
+	logger := logrus.StandardLogger()
+	logger.Hooks.Add(newPidHostnameHook())
+	logger.Formatter = &TextFormatter{}
+
 	return &FileLogger{
 		filename:    "",
 		fd:          nil,
+		format:      FormatText,
 		watcherDone: nil,
-		Logger:      logrus.StandardLogger(),
+		Logger:      logger,
 	}
 }
 
-// Open file for logging
+// pidHostnameHook stamps every entry with the fields operators expect to
+// find on every line once logs leave the host (pid, hostname), regardless
+// of which formatter is in use.
+type pidHostnameHook struct {
+	pid      int
+	hostname string
+}
+
+func newPidHostnameHook() *pidHostnameHook {
+	hostname, _ := os.Hostname()
+	return &pidHostnameHook{pid: os.Getpid(), hostname: hostname}
+}
+
+func (h *pidHostnameHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *pidHostnameHook) Fire(entry *logrus.Entry) error {
+	if _, ok := entry.Data["pid"]; !ok {
+		entry.Data["pid"] = h.pid
+	}
+	if _, ok := entry.Data["hostname"]; !ok {
+		entry.Data["hostname"] = h.hostname
+	}
+	if _, ok := entry.Data["component"]; !ok {
+		entry.Data["component"] = "carbon"
+	}
+	return nil
+}
+
+// SetFormat switches the standard logger between "text" (default) and
+// "json" output. It is safe to call at any time, including from the
+// SIGHUP config-reload path, so operators can flip formats without a
+// restart.
+func (l *FileLogger) SetFormat(format string) error {
+	var f logrus.Formatter
+
+	switch Format(format) {
+	case "", FormatText:
+		f = &TextFormatter{}
+	case FormatJSON:
+		f = &logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano}
+	default:
+		return fmt.Errorf("unknown log format %#v", format)
+	}
+
+	l.Lock()
+	l.format = Format(format)
+	l.Logger.Formatter = f
+	l.Unlock()
+
+	return nil
+}
+
+// Format returns the currently configured output format.
+func (l *FileLogger) Format() Format {
+	l.RLock()
+	defer l.RUnlock()
+	return l.format
+}
+
+// SetFormat sets the output format ("text"|"json") for the default logger.
+func SetFormat(format string) error {
+	return std.SetFormat(format)
+}
+
+// Open file for logging. filename may also be a "syslog://" target, in
+// which case entries are forwarded to syslog instead of a regular file
+// and the fsnotify watcher below is skipped.
 func (l *FileLogger) Open(filename string) error {
 	l.Lock()
 	l.filename = filename
@@ -50,9 +139,13 @@ func (l *FileLogger) Open(filename string) error {
 	reopenErr := l.Reopen()
 	if l.watcherDone != nil {
 		close(l.watcherDone)
+		l.watcherDone = nil
+	}
+
+	if !isSyslogTarget(filename) {
+		l.watcherDone = make(chan bool)
+		l.fsWatch(l.filename, l.watcherDone)
 	}
-	l.watcherDone = make(chan bool)
-	l.fsWatch(l.filename, l.watcherDone)
 
 	return reopenErr
 }
@@ -98,32 +191,80 @@ func (l *FileLogger) fsWatch(filename string, quit chan bool) {
 	}()
 }
 
-// Reopen file
+// Reopen file (or reconnect the syslog destination, for a "syslog://"
+// target). Called both on startup and on every SIGHUP.
 func (l *FileLogger) Reopen() error {
 	l.Lock()
 	defer l.Unlock()
 
+	if isSyslogTarget(l.filename) {
+		target, err := parseSyslogTarget(l.filename)
+		if err != nil {
+			return err
+		}
+
+		newHook, err := dialSyslogHook(target)
+		if err != nil {
+			return err
+		}
+
+		if l.fd != nil {
+			l.fd.Close()
+			l.fd = nil
+		}
+
+		oldHook := l.syslogHook
+		l.syslogHook = newHook
+		replaceHook(l.Logger, oldHook, newHook)
+		if oldHook != nil {
+			oldHook.Close()
+		}
+
+		logrus.SetOutput(ioutil.Discard)
+		return nil
+	}
+
+	if l.syslogHook != nil {
+		replaceHook(l.Logger, l.syslogHook, nil)
+		l.syslogHook.Close()
+		l.syslogHook = nil
+	}
+
+	if l.rotating != nil {
+		l.rotating.Close()
+		l.rotating = nil
+	}
+
 	var newFd *os.File
+	var newRotating *rotatingFile
 	var err error
 
 	if l.filename != "" {
-		newFd, err = os.OpenFile(l.filename, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
-
-		if err != nil {
-			return err
+		if l.rotateCfg.enabled() {
+			newRotating, err = openRotatingFile(l.filename, l.rotateCfg)
+			if err != nil {
+				return err
+			}
+		} else {
+			newFd, err = os.OpenFile(l.filename, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+			if err != nil {
+				return err
+			}
 		}
-	} else {
-		newFd = nil
 	}
 
 	oldFd := l.fd
 	l.fd = newFd
+	l.rotating = newRotating
 
 	var loggerOut io.Writer
 
-	if l.fd != nil {
+	switch {
+	case l.rotating != nil:
+		loggerOut = l.rotating
+	case l.fd != nil:
 		loggerOut = l.fd
-	} else {
+	default:
 		loggerOut = os.Stderr
 	}
 	logrus.SetOutput(loggerOut)
@@ -135,6 +276,21 @@ func (l *FileLogger) Reopen() error {
 	return nil
 }
 
+// SetRotateConfig configures built-in log rotation (cfg.Common.LogRotate).
+// It takes effect on the next Reopen/SIGHUP; pass a zero-value
+// RotateConfig to fall back to plain append-mode files managed by an
+// external logrotate.
+func (l *FileLogger) SetRotateConfig(cfg RotateConfig) {
+	l.Lock()
+	l.rotateCfg = cfg
+	l.Unlock()
+}
+
+// SetRotateConfig configures built-in log rotation for the default logger.
+func SetRotateConfig(cfg RotateConfig) {
+	std.SetRotateConfig(cfg)
+}
+
 // Filename returns current filename
 func (l *FileLogger) Filename() string {
 	l.RLock()
@@ -148,13 +304,36 @@ func SetFile(filename string) error {
 }
 
 // SetLevel for default logger
+// SetLevel sets the default log level for the standard logger. lvl may
+// also be a comma-separated list mixing a bare default level with
+// "component=level" overrides (e.g. "info,cache=debug,persister=warn"),
+// in which case the per-component overrides are applied via
+// SetComponentLevels alongside the default.
 func SetLevel(lvl string) error {
-	level, err := logrus.ParseLevel(lvl)
-	if err != nil {
-		return err
+	overrides := make(map[string]string)
+
+	for _, part := range strings.Split(lvl, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if name, level, ok := splitComponentLevel(part); ok {
+			overrides[name] = level
+			continue
+		}
+
+		level, err := logrus.ParseLevel(part)
+		if err != nil {
+			return err
+		}
+		logrus.SetLevel(level)
 	}
-	logrus.SetLevel(level)
-	return nil
+
+	// SetComponentLevels replaces the full override set, so this must run
+	// unconditionally: a reload with no "component=level" pairs means the
+	// operator wants the overrides gone, not left stale from before.
+	return SetComponentLevels(overrides)
 }
 
 // PrepareFile creates logfile and set it writable for user
@@ -225,9 +404,12 @@ func Test(callable func(TestOut)) {
 	callable(buf)
 
 	var loggerOut io.Writer
-	if std.fd != nil {
+	switch {
+	case std.rotating != nil:
+		loggerOut = std.rotating
+	case std.fd != nil:
 		loggerOut = std.fd
-	} else {
+	default:
 		loggerOut = os.Stderr
 	}
 
@@ -244,27 +426,26 @@ func TestWithLevel(level string, callable func(TestOut)) {
 }
 
 // WithError creates an entry from the standard logger and adds an error to it, using the value defined in ErrorKey as key.
-func WithError(err error) *logrus.Entry {
-	return std.WithField("error", err)
+func WithError(err error) Logger {
+	return std.WithError(err)
 }
 
-// WithField creates an entry from the standard logger and adds a field to
-// it. If you want multiple fields, use `WithFields`.
+// WithField creates a Logger from the standard logger with a field
+// attached. If you want multiple fields, use `WithFields`.
 //
-// Note that it doesn't log until you call Debug, Print, Info, Warn, Fatal
-// or Panic on the Entry it returns.
-func WithField(key string, value interface{}) *logrus.Entry {
+// Note that it doesn't log until you call Debug, Info, Warn, Error or
+// Fatal on the Logger it returns.
+func WithField(key string, value interface{}) Logger {
 	return std.WithField(key, value)
 }
 
-// WithFields creates an entry from the standard logger and adds multiple
-// fields to it. This is simply a helper for `WithField`, invoking it
-// once for each field.
+// WithFields creates a Logger from the standard logger with multiple
+// fields attached.
 //
-// Note that it doesn't log until you call Debug, Print, Info, Warn, Fatal
-// or Panic on the Entry it returns.
-func WithFields(fields Fields) *logrus.Entry {
-	return std.WithFields(logrus.Fields(fields))
+// Note that it doesn't log until you call Debug, Info, Warn, Error or
+// Fatal on the Logger it returns.
+func WithFields(fields Fields) Logger {
+	return std.WithFields(fields)
 }
 
 // Debug logs a message at level Debug on the standard logger.
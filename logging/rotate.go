@@ -0,0 +1,204 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RotateConfig controls the built-in log rotation driven by
+// cfg.Common.LogRotate. A zero-value RotateConfig leaves rotation
+// disabled, so a plain file is opened in append mode and external
+// logrotate (paired with SIGHUP) keeps working exactly as before.
+type RotateConfig struct {
+	MaxSizeMB      int    // rotate once the active file exceeds this size; 0 disables size-based rotation
+	MaxAgeDays     int    // remove rotated files older than this many days; 0 keeps them forever
+	MaxBackups     int    // keep at most this many rotated files; 0 keeps them all
+	Compress       bool   // gzip rotated files in the background
+	RotateInterval string // "", "hourly" or "daily" - time-based rotation
+}
+
+func (c RotateConfig) enabled() bool {
+	return c.MaxSizeMB > 0 || c.RotateInterval != ""
+}
+
+// rotatingFile is an io.Writer over a single log file that rotates
+// itself by size and/or time, renaming the active file aside, gzipping
+// it in the background when configured, and pruning old backups by
+// age/count.
+type rotatingFile struct {
+	sync.Mutex
+	filename string
+	cfg      RotateConfig
+	fd       *os.File
+	size     int64
+	openedAt time.Time
+}
+
+func openRotatingFile(filename string, cfg RotateConfig) (*rotatingFile, error) {
+	rf := &rotatingFile{filename: filename, cfg: cfg}
+	if err := rf.openCurrent(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *rotatingFile) openCurrent() error {
+	fd, err := os.OpenFile(rf.filename, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := fd.Stat()
+	if err != nil {
+		fd.Close()
+		return err
+	}
+
+	rf.fd = fd
+	rf.size = info.Size()
+	rf.openedAt = time.Now()
+	return nil
+}
+
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	rf.Lock()
+	defer rf.Unlock()
+
+	if rf.shouldRotate(len(p)) {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.fd.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+func (rf *rotatingFile) shouldRotate(nextWrite int) bool {
+	if rf.cfg.MaxSizeMB > 0 && rf.size+int64(nextWrite) > int64(rf.cfg.MaxSizeMB)*1024*1024 {
+		return true
+	}
+
+	switch rf.cfg.RotateInterval {
+	case "daily":
+		now := time.Now()
+		return now.Year() != rf.openedAt.Year() || now.YearDay() != rf.openedAt.YearDay()
+	case "hourly":
+		return !time.Now().Truncate(time.Hour).Equal(rf.openedAt.Truncate(time.Hour))
+	}
+
+	return false
+}
+
+// rotate renames the active file aside and opens a fresh one in its
+// place, then prunes/compresses backups in the background so writers
+// aren't blocked on I/O.
+func (rf *rotatingFile) rotate() error {
+	if rf.fd != nil {
+		rf.fd.Close()
+		rf.fd = nil
+	}
+
+	rotated := fmt.Sprintf("%s.%s", rf.filename, time.Now().Format("20060102T150405.000"))
+	if err := os.Rename(rf.filename, rotated); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := rf.openCurrent(); err != nil {
+		return err
+	}
+
+	cfg := rf.cfg
+	filename := rf.filename
+	go func() {
+		if cfg.Compress {
+			if err := gzipFile(rotated); err != nil {
+				std.Logger.Warnf("logging: failed to compress rotated log %#v: %s", rotated, err)
+			}
+		}
+		prune(filename, cfg)
+	}()
+
+	return nil
+}
+
+func (rf *rotatingFile) Close() error {
+	rf.Lock()
+	defer rf.Unlock()
+
+	if rf.fd == nil {
+		return nil
+	}
+	return rf.fd.Close()
+}
+
+func gzipFile(name string) error {
+	in, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(name + ".gz")
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		gz.Close()
+		out.Close()
+		os.Remove(name + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(name)
+}
+
+// prune removes rotated backups of filename that are older than
+// cfg.MaxAgeDays and/or beyond the cfg.MaxBackups most recent ones.
+func prune(filename string, cfg RotateConfig) {
+	if cfg.MaxAgeDays <= 0 && cfg.MaxBackups <= 0 {
+		return
+	}
+
+	matches, err := filepath.Glob(filename + ".*")
+	if err != nil {
+		return
+	}
+	sort.Strings(matches)
+
+	if cfg.MaxAgeDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -cfg.MaxAgeDays)
+		kept := matches[:0]
+		for _, m := range matches {
+			info, err := os.Stat(m)
+			if err != nil || info.ModTime().Before(cutoff) {
+				os.Remove(m)
+				continue
+			}
+			kept = append(kept, m)
+		}
+		matches = kept
+	}
+
+	if cfg.MaxBackups > 0 && len(matches) > cfg.MaxBackups {
+		for _, m := range matches[:len(matches)-cfg.MaxBackups] {
+			os.Remove(m)
+		}
+	}
+}
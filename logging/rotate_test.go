@@ -0,0 +1,138 @@
+package logging
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotateConfigEnabled(t *testing.T) {
+	cases := []struct {
+		cfg     RotateConfig
+		enabled bool
+	}{
+		{cfg: RotateConfig{}, enabled: false},
+		{cfg: RotateConfig{MaxSizeMB: 100}, enabled: true},
+		{cfg: RotateConfig{RotateInterval: "daily"}, enabled: true},
+		{cfg: RotateConfig{MaxAgeDays: 7, MaxBackups: 5}, enabled: false},
+	}
+
+	for _, c := range cases {
+		if got := c.cfg.enabled(); got != c.enabled {
+			t.Errorf("RotateConfig(%+v).enabled() = %v, want %v", c.cfg, got, c.enabled)
+		}
+	}
+}
+
+func TestRotatingFileShouldRotateBySize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-carbon-rotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	rf, err := openRotatingFile(filepath.Join(dir, "go-carbon.log"), RotateConfig{MaxSizeMB: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+
+	if rf.shouldRotate(10) {
+		t.Error("expected a fresh, empty file not to need rotation")
+	}
+
+	rf.size = 2 * 1024 * 1024
+	if !rf.shouldRotate(10) {
+		t.Error("expected rotation once size exceeds MaxSizeMB")
+	}
+}
+
+func TestRotatingFileShouldRotateDaily(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-carbon-rotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	rf, err := openRotatingFile(filepath.Join(dir, "go-carbon.log"), RotateConfig{RotateInterval: "daily"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+
+	if rf.shouldRotate(0) {
+		t.Error("expected no rotation immediately after opening")
+	}
+
+	rf.openedAt = rf.openedAt.AddDate(0, 0, -1)
+	if !rf.shouldRotate(0) {
+		t.Error("expected rotation once a day has elapsed")
+	}
+}
+
+func TestPruneByBackupCount(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-carbon-prune")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	base := filepath.Join(dir, "go-carbon.log")
+	var names []string
+	for i := 0; i < 5; i++ {
+		name := base + ".20200101T00000" + string(rune('0'+i))
+		if err := ioutil.WriteFile(name, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+
+	prune(base, RotateConfig{MaxBackups: 2})
+
+	remaining, err := filepath.Glob(base + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 2 {
+		t.Fatalf("expected 2 backups to remain, got %d: %v", len(remaining), remaining)
+	}
+	for _, name := range names[:3] {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("expected %s to have been pruned", name)
+		}
+	}
+}
+
+func TestPruneByAge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-carbon-prune")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	base := filepath.Join(dir, "go-carbon.log")
+	old := base + ".old"
+	fresh := base + ".fresh"
+
+	for _, name := range []string{old, fresh} {
+		if err := ioutil.WriteFile(name, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldTime := time.Now().AddDate(0, 0, -30)
+	if err := os.Chtimes(old, oldTime, oldTime); err != nil {
+		t.Fatal(err)
+	}
+
+	prune(base, RotateConfig{MaxAgeDays: 7})
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("expected the old backup to have been pruned")
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("expected the fresh backup to survive: %s", err)
+	}
+}
@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"log/syslog"
+	"testing"
+)
+
+func TestParseSyslogTarget(t *testing.T) {
+	cases := []struct {
+		raw      string
+		network  string
+		addr     string
+		facility syslog.Priority
+		useTLS   bool
+		wantErr  bool
+	}{
+		{raw: "syslog://local0", facility: syslog.LOG_LOCAL0},
+		{raw: "syslog://", facility: syslog.LOG_DAEMON},
+		{raw: "syslog://udp/127.0.0.1:514", network: "udp", addr: "127.0.0.1:514", facility: syslog.LOG_DAEMON},
+		{raw: "syslog://tcp/logs.example.com:601?facility=local5", network: "tcp", addr: "logs.example.com:601", facility: syslog.LOG_LOCAL5},
+		{raw: "syslog://tls/logs.example.com:6514", network: "tcp", addr: "logs.example.com:6514", facility: syslog.LOG_DAEMON, useTLS: true},
+		{raw: "syslog://udp/host:1?facility=bogus", wantErr: true},
+		{raw: "syslog://sctp/host:1", wantErr: true},
+	}
+
+	for _, c := range cases {
+		target, err := parseSyslogTarget(c.raw)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("parseSyslogTarget(%q): expected error, got %+v", c.raw, target)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("parseSyslogTarget(%q): unexpected error: %s", c.raw, err)
+		}
+		if target.network != c.network || target.addr != c.addr || target.facility != c.facility || target.useTLS != c.useTLS {
+			t.Errorf("parseSyslogTarget(%q) = %+v, want {network:%q addr:%q facility:%v useTLS:%v}",
+				c.raw, target, c.network, c.addr, c.facility, c.useTLS)
+		}
+	}
+}
+
+func TestIsSyslogTarget(t *testing.T) {
+	if !isSyslogTarget("syslog://local0") {
+		t.Error("expected syslog:// target to be detected")
+	}
+	if isSyslogTarget("/var/log/go-carbon/go-carbon.log") {
+		t.Error("did not expect a regular path to be detected as a syslog target")
+	}
+}